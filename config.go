@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProductConfig describes a single product to monitor.
+type ProductConfig struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url"`
+	Strategy string `yaml:"strategy"` // opengraph (default), jsonld, css_text, json_api
+
+	// Selector is used by the opengraph, jsonld and css_text strategies.
+	Selector string `yaml:"selector"`
+
+	// InStockText/OutOfStockText are used by the css_text strategy.
+	InStockText    string `yaml:"in_stock_text"`
+	OutOfStockText string `yaml:"out_of_stock_text"`
+
+	// JSONAPIURL/JSONAPIField are used by the json_api strategy.
+	JSONAPIURL   string `yaml:"json_api_url"`
+	JSONAPIField string `yaml:"json_api_field"`
+
+	PollInterval      time.Duration `yaml:"poll_interval"`
+	DiscordWebhookURL string        `yaml:"discord_webhook_url"`
+	DiscordUserID     string        `yaml:"discord_user_id"`
+	NotifyCooldown    time.Duration `yaml:"notify_cooldown"`
+
+	// JitterPercent adds up to ±N% randomness to every poll interval.
+	JitterPercent float64 `yaml:"jitter_percent"`
+
+	// BackoffCeiling caps how far PollInterval is allowed to double on
+	// consecutive 429/403/5xx responses.
+	BackoffCeiling time.Duration `yaml:"backoff_ceiling"`
+
+	// OffHoursStart/OffHoursEnd ("15:04") define a daily window, which may
+	// wrap past midnight, during which polling slows by OffHoursMultiplier.
+	OffHoursStart      string  `yaml:"off_hours_start"`
+	OffHoursEnd        string  `yaml:"off_hours_end"`
+	OffHoursMultiplier float64 `yaml:"off_hours_multiplier"`
+
+	// ConfirmChecks is how many faster polls (at ConfirmInterval) follow a
+	// state change, to confirm it isn't a flap. ConfirmInterval defaults to
+	// PollInterval if unset.
+	ConfirmChecks   int           `yaml:"confirm_checks"`
+	ConfirmInterval time.Duration `yaml:"confirm_interval"`
+}
+
+// ProxyPoolConfig configures the shared, health-checked proxy pool used by
+// every product.
+type ProxyPoolConfig struct {
+	CheckURL           string        `yaml:"check_url"`
+	CheckInterval      time.Duration `yaml:"check_interval"`
+	ErrorThreshold     int           `yaml:"error_threshold"`
+	QuarantineCooldown time.Duration `yaml:"quarantine_cooldown"`
+	Strategy           string        `yaml:"strategy"` // round_robin (default), random, least_latency, weighted
+}
+
+// ProxyStrategy identifies a ProxyPool rotation strategy.
+type ProxyStrategy = string
+
+const (
+	ProxyStrategyRoundRobin   ProxyStrategy = "round_robin"
+	ProxyStrategyRandom       ProxyStrategy = "random"
+	ProxyStrategyLeastLatency ProxyStrategy = "least_latency"
+	ProxyStrategyWeighted     ProxyStrategy = "weighted"
+)
+
+const (
+	defaultProxyCheckURL           = "https://api.ipify.org"
+	defaultProxyCheckInterval      = 5 * time.Minute
+	defaultProxyErrorThreshold     = 3
+	defaultProxyQuarantineCooldown = 10 * time.Minute
+)
+
+const (
+	defaultJitterPercent  = 10
+	defaultBackoffCeiling = 10 * time.Minute
+	defaultConfirmChecks  = 3
+)
+
+const defaultMetricsPort = 9090
+
+// Config is the top-level monitor configuration loaded from config.yml.
+type Config struct {
+	PollInterval   time.Duration `yaml:"poll_interval"`
+	NotifyCooldown time.Duration `yaml:"notify_cooldown"`
+
+	JitterPercent      float64       `yaml:"jitter_percent"`
+	BackoffCeiling     time.Duration `yaml:"backoff_ceiling"`
+	OffHoursStart      string        `yaml:"off_hours_start"`
+	OffHoursEnd        string        `yaml:"off_hours_end"`
+	OffHoursMultiplier float64       `yaml:"off_hours_multiplier"`
+	ConfirmChecks      int           `yaml:"confirm_checks"`
+	ConfirmInterval    time.Duration `yaml:"confirm_interval"`
+
+	// MetricsPort serves /metrics (Prometheus) and /healthz. Defaults to
+	// 9090 if unset.
+	MetricsPort int `yaml:"metrics_port"`
+
+	ProxyPool  ProxyPoolConfig  `yaml:"proxy_pool"`
+	StateStore StateStoreConfig `yaml:"state_store"`
+	Products   []ProductConfig  `yaml:"products"`
+}
+
+// StateStoreConfig configures how product state is persisted across
+// restarts.
+type StateStoreConfig struct {
+	Backend string `yaml:"backend"` // file (default) or bolt
+	Path    string `yaml:"path"`
+}
+
+const (
+	StateStoreFile = "file"
+	StateStoreBolt = "bolt"
+
+	defaultStateFilePath = "state.json"
+	defaultStateBoltPath = "state.db"
+)
+
+// LoadConfig reads and validates the monitor configuration at filename.
+// Product-level fields fall back to the top-level defaults, and the whole
+// config is validated up front so a typo fails fast instead of silently
+// monitoring nothing.
+func LoadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %v", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %v", err)
+	}
+
+	if len(cfg.Products) == 0 {
+		return nil, fmt.Errorf("config must define at least one product under products:")
+	}
+
+	if cfg.MetricsPort == 0 {
+		cfg.MetricsPort = defaultMetricsPort
+	}
+
+	switch cfg.StateStore.Backend {
+	case "":
+		cfg.StateStore.Backend = StateStoreFile
+	case StateStoreFile, StateStoreBolt:
+	default:
+		return nil, fmt.Errorf("state_store: unknown backend %q", cfg.StateStore.Backend)
+	}
+	if cfg.StateStore.Path == "" {
+		if cfg.StateStore.Backend == StateStoreBolt {
+			cfg.StateStore.Path = defaultStateBoltPath
+		} else {
+			cfg.StateStore.Path = defaultStateFilePath
+		}
+	}
+
+	switch cfg.ProxyPool.Strategy {
+	case "", ProxyStrategyRoundRobin, ProxyStrategyRandom, ProxyStrategyLeastLatency, ProxyStrategyWeighted:
+	default:
+		return nil, fmt.Errorf("proxy_pool: unknown strategy %q", cfg.ProxyPool.Strategy)
+	}
+	if cfg.ProxyPool.CheckURL == "" {
+		cfg.ProxyPool.CheckURL = defaultProxyCheckURL
+	}
+	if cfg.ProxyPool.CheckInterval <= 0 {
+		cfg.ProxyPool.CheckInterval = defaultProxyCheckInterval
+	}
+	if cfg.ProxyPool.ErrorThreshold <= 0 {
+		cfg.ProxyPool.ErrorThreshold = defaultProxyErrorThreshold
+	}
+	if cfg.ProxyPool.QuarantineCooldown <= 0 {
+		cfg.ProxyPool.QuarantineCooldown = defaultProxyQuarantineCooldown
+	}
+
+	seen := make(map[string]bool, len(cfg.Products))
+	for i := range cfg.Products {
+		p := &cfg.Products[i]
+
+		if p.Name == "" {
+			return nil, fmt.Errorf("product %d: name is required", i)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("product %q: name is duplicated", p.Name)
+		}
+		seen[p.Name] = true
+
+		if p.URL == "" {
+			return nil, fmt.Errorf("product %q: url is required", p.Name)
+		}
+		if p.DiscordWebhookURL == "" {
+			return nil, fmt.Errorf("product %q: discord_webhook_url is required", p.Name)
+		}
+		if p.DiscordUserID == "" {
+			return nil, fmt.Errorf("product %q: discord_user_id is required", p.Name)
+		}
+
+		if Strategy(p.Strategy) == StrategyCSSText && p.Selector == "" {
+			return nil, fmt.Errorf("product %q: css_text strategy requires selector", p.Name)
+		}
+		if _, err := NewStockDetector(*p); err != nil {
+			return nil, fmt.Errorf("product %q: %v", p.Name, err)
+		}
+
+		if p.PollInterval == 0 {
+			p.PollInterval = cfg.PollInterval
+		}
+		if p.PollInterval <= 0 {
+			return nil, fmt.Errorf("product %q: poll_interval must be set (either on the product or as a top-level default)", p.Name)
+		}
+
+		if p.NotifyCooldown == 0 {
+			p.NotifyCooldown = cfg.NotifyCooldown
+		}
+
+		if p.JitterPercent == 0 {
+			p.JitterPercent = cfg.JitterPercent
+		}
+		if p.JitterPercent == 0 {
+			p.JitterPercent = defaultJitterPercent
+		}
+		if p.JitterPercent < 0 || p.JitterPercent >= 100 {
+			return nil, fmt.Errorf("product %q: jitter_percent must be in [0, 100)", p.Name)
+		}
+
+		if p.BackoffCeiling == 0 {
+			p.BackoffCeiling = cfg.BackoffCeiling
+		}
+		if p.BackoffCeiling == 0 {
+			p.BackoffCeiling = defaultBackoffCeiling
+		}
+
+		if p.OffHoursStart == "" {
+			p.OffHoursStart = cfg.OffHoursStart
+		}
+		if p.OffHoursEnd == "" {
+			p.OffHoursEnd = cfg.OffHoursEnd
+		}
+		if p.OffHoursMultiplier == 0 {
+			p.OffHoursMultiplier = cfg.OffHoursMultiplier
+		}
+
+		if p.ConfirmChecks == 0 {
+			p.ConfirmChecks = cfg.ConfirmChecks
+		}
+		if p.ConfirmChecks == 0 {
+			p.ConfirmChecks = defaultConfirmChecks
+		}
+		if p.ConfirmInterval == 0 {
+			p.ConfirmInterval = cfg.ConfirmInterval
+		}
+		if p.ConfirmInterval == 0 {
+			p.ConfirmInterval = p.PollInterval
+		}
+	}
+
+	return &cfg, nil
+}
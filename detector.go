@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Strategy identifies which StockDetector implementation a product uses.
+type Strategy string
+
+const (
+	StrategyOpenGraph Strategy = "opengraph"
+	StrategyJSONLD    Strategy = "jsonld"
+	StrategyCSSText   Strategy = "css_text"
+	StrategyJSONAPI   Strategy = "json_api"
+)
+
+// StockDetector decides whether a product page or API response indicates
+// the item is in stock. A colly.Visit call runs all of a collector's
+// registered callbacks synchronously before returning, so implementations
+// just wire themselves onto the collector and call report once they've
+// found their signal.
+type StockDetector interface {
+	// Register wires the detector's callbacks onto c. report should be
+	// called with the in-stock verdict and the raw text/value that drove
+	// it, for logging.
+	Register(c *colly.Collector, report func(inStock bool, raw string))
+}
+
+// NewStockDetector builds the StockDetector configured for p.
+func NewStockDetector(p ProductConfig) (StockDetector, error) {
+	switch Strategy(p.Strategy) {
+	case "", StrategyOpenGraph:
+		return &openGraphDetector{}, nil
+
+	case StrategyJSONLD:
+		return &jsonLDDetector{}, nil
+
+	case StrategyCSSText:
+		if p.InStockText == "" && p.OutOfStockText == "" {
+			return nil, fmt.Errorf("css_text strategy requires in_stock_text or out_of_stock_text")
+		}
+		return &cssTextDetector{
+			selector:       p.Selector,
+			inStockText:    p.InStockText,
+			outOfStockText: p.OutOfStockText,
+		}, nil
+
+	case StrategyJSONAPI:
+		if p.JSONAPIURL == "" || p.JSONAPIField == "" {
+			return nil, fmt.Errorf("json_api strategy requires json_api_url and json_api_field")
+		}
+		return &jsonAPIDetector{fieldPath: p.JSONAPIField}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown strategy %q", p.Strategy)
+	}
+}
+
+// openGraphDetector reads the og:availability meta tag. This is the
+// monitor's original detection method.
+type openGraphDetector struct{}
+
+func (d *openGraphDetector) Register(c *colly.Collector, report func(bool, string)) {
+	c.OnHTML("meta[property='og:availability']", func(e *colly.HTMLElement) {
+		availability := e.Attr("content")
+		report(openGraphInStock(availability), availability)
+	})
+}
+
+// openGraphInStock interprets an og:availability meta content value.
+func openGraphInStock(availability string) bool {
+	return strings.ToLower(availability) != "out of stock"
+}
+
+// jsonLDProduct captures the fields of a schema.org Product JSON-LD node
+// that are relevant to stock detection.
+type jsonLDProduct struct {
+	Offers struct {
+		Availability string `json:"availability"`
+	} `json:"offers"`
+}
+
+// jsonLDDetector reads the availability field out of a Product JSON-LD
+// <script> block, as used by Shopify, BigCommerce and WooCommerce storefronts.
+type jsonLDDetector struct{}
+
+func (d *jsonLDDetector) Register(c *colly.Collector, report func(bool, string)) {
+	c.OnHTML("script[type='application/ld+json']", func(e *colly.HTMLElement) {
+		availability, ok := parseJSONLDAvailability([]byte(e.Text))
+		if !ok {
+			return
+		}
+		report(jsonLDInStock(availability), availability)
+	})
+}
+
+// jsonLDInStock interprets a schema.org offers.availability value (e.g.
+// "https://schema.org/InStock" or "InStock").
+func jsonLDInStock(availability string) bool {
+	return strings.Contains(strings.ToLower(availability), "instock")
+}
+
+// parseJSONLDAvailability extracts the first non-empty Product
+// offers.availability value out of a JSON-LD block. Storefronts emit this
+// as a single object, an array of objects, or an object wrapping an
+// "@graph" array, so every node is checked in turn.
+func parseJSONLDAvailability(raw []byte) (string, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return "", false
+	}
+
+	for _, node := range jsonLDNodes(doc) {
+		data, err := json.Marshal(node)
+		if err != nil {
+			continue
+		}
+
+		var product jsonLDProduct
+		if err := json.Unmarshal(data, &product); err != nil {
+			continue
+		}
+		if product.Offers.Availability != "" {
+			return product.Offers.Availability, true
+		}
+	}
+
+	return "", false
+}
+
+// jsonLDNodes flattens a decoded JSON-LD document into the candidate nodes
+// to inspect for Product data: the document itself, each element of a
+// top-level array, or each element of an "@graph" array.
+func jsonLDNodes(doc interface{}) []interface{} {
+	switch v := doc.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		if graph, ok := v["@graph"].([]interface{}); ok {
+			return graph
+		}
+		return []interface{}{v}
+	default:
+		return nil
+	}
+}
+
+// cssTextDetector matches the presence of configured text (e.g. "Sold Out"
+// or "Add to Cart") inside a CSS-selected element.
+type cssTextDetector struct {
+	selector       string
+	inStockText    string
+	outOfStockText string
+}
+
+func (d *cssTextDetector) Register(c *colly.Collector, report func(bool, string)) {
+	// A selector can match more than one element (e.g. one per variant), so
+	// text is accumulated across all of them and a single verdict is
+	// reported once the whole page has been scraped. Reporting per element
+	// would let one out-of-stock variant and one in-stock variant emit two
+	// conflicting reports for the same check.
+	var texts []string
+
+	c.OnHTML(d.selector, func(e *colly.HTMLElement) {
+		texts = append(texts, strings.TrimSpace(e.Text))
+	})
+
+	c.OnScraped(func(r *colly.Response) {
+		combined := strings.Join(texts, " ")
+		texts = nil
+
+		inStock, matched := cssTextVerdict(combined, d.inStockText, d.outOfStockText)
+		if matched {
+			report(inStock, combined)
+		}
+	})
+}
+
+// cssTextVerdict checks text for the configured out-of-stock text before
+// the in-stock text, since a page can legitimately contain both (e.g. a
+// "Notify when back in stock" link alongside a "Sold Out" label).
+func cssTextVerdict(text, inStockText, outOfStockText string) (inStock, matched bool) {
+	switch {
+	case outOfStockText != "" && strings.Contains(text, outOfStockText):
+		return false, true
+	case inStockText != "" && strings.Contains(text, inStockText):
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// jsonAPIDetector probes a JSON API endpoint and pulls a stock signal out
+// of the response body at fieldPath, a dot-separated accessor (e.g.
+// "variants.0.available").
+type jsonAPIDetector struct {
+	fieldPath string
+}
+
+func (d *jsonAPIDetector) Register(c *colly.Collector, report func(bool, string)) {
+	c.OnResponse(func(r *colly.Response) {
+		var data interface{}
+		if err := json.Unmarshal(r.Body, &data); err != nil {
+			return
+		}
+
+		value, ok := lookupJSONPath(data, d.fieldPath)
+		if !ok {
+			return
+		}
+
+		report(isStockValue(value), fmt.Sprintf("%v", value))
+	})
+}
+
+// lookupJSONPath walks a dot-separated path through a decoded JSON value,
+// indexing into arrays by integer segments.
+func lookupJSONPath(data interface{}, path string) (interface{}, bool) {
+	current := data
+
+	for _, seg := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			val, ok := v[seg]
+			if !ok {
+				return nil, false
+			}
+			current = val
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			current = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// isStockValue interprets a value pulled from a JSON API response as an
+// in-stock signal.
+func isStockValue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		lower := strings.ToLower(v)
+		return lower == "true" || strings.Contains(lower, "instock") || lower == "in_stock"
+	case float64:
+		return v > 0
+	default:
+		return false
+	}
+}
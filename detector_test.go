@@ -0,0 +1,180 @@
+package main
+
+import "testing"
+
+func TestOpenGraphInStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		availability string
+		want         bool
+	}{
+		{"in stock", "in stock", true},
+		{"out of stock", "out of stock", false},
+		{"case insensitive out of stock", "Out Of Stock", false},
+		{"anything else counts as in stock", "preorder", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := openGraphInStock(tt.availability); got != tt.want {
+				t.Errorf("openGraphInStock(%q) = %v, want %v", tt.availability, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONLDAvailability(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "single object",
+			raw:    `{"@type":"Product","offers":{"availability":"https://schema.org/InStock"}}`,
+			want:   "https://schema.org/InStock",
+			wantOK: true,
+		},
+		{
+			name:   "array of objects",
+			raw:    `[{"@type":"Product","offers":{"availability":"https://schema.org/OutOfStock"}}]`,
+			want:   "https://schema.org/OutOfStock",
+			wantOK: true,
+		},
+		{
+			name:   "graph wrapped",
+			raw:    `{"@graph":[{"@type":"BreadcrumbList"},{"@type":"Product","offers":{"availability":"InStock"}}]}`,
+			want:   "InStock",
+			wantOK: true,
+		},
+		{
+			name:   "no offers anywhere",
+			raw:    `{"@type":"BreadcrumbList"}`,
+			wantOK: false,
+		},
+		{
+			name:   "invalid json",
+			raw:    `not json`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseJSONLDAvailability([]byte(tt.raw))
+			if ok != tt.wantOK {
+				t.Fatalf("parseJSONLDAvailability(%q) ok = %v, want %v", tt.raw, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("parseJSONLDAvailability(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLDInStock(t *testing.T) {
+	tests := []struct {
+		availability string
+		want         bool
+	}{
+		{"https://schema.org/InStock", true},
+		{"InStock", true},
+		{"https://schema.org/OutOfStock", false},
+		{"PreOrder", false},
+	}
+
+	for _, tt := range tests {
+		if got := jsonLDInStock(tt.availability); got != tt.want {
+			t.Errorf("jsonLDInStock(%q) = %v, want %v", tt.availability, got, tt.want)
+		}
+	}
+}
+
+func TestCSSTextVerdict(t *testing.T) {
+	tests := []struct {
+		name                          string
+		text, inStockText, outOfStock string
+		wantInStock, wantMatched      bool
+	}{
+		{"matches out of stock", "Sold Out", "Add to Cart", "Sold Out", false, true},
+		{"matches in stock", "Add to Cart", "Add to Cart", "Sold Out", true, true},
+		{"out of stock checked first when both present", "Add to Cart / Sold Out", "Add to Cart", "Sold Out", false, true},
+		{"no match", "Coming Soon", "Add to Cart", "Sold Out", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inStock, matched := cssTextVerdict(tt.text, tt.inStockText, tt.outOfStock)
+			if matched != tt.wantMatched || (matched && inStock != tt.wantInStock) {
+				t.Errorf("cssTextVerdict(%q, %q, %q) = (%v, %v), want (%v, %v)",
+					tt.text, tt.inStockText, tt.outOfStock, inStock, matched, tt.wantInStock, tt.wantMatched)
+			}
+		})
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	data := map[string]interface{}{
+		"product": map[string]interface{}{
+			"variants": []interface{}{
+				map[string]interface{}{"available": true},
+				map[string]interface{}{"available": false},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   interface{}
+		wantOK bool
+	}{
+		{"nested map and array index", "product.variants.0.available", true, true},
+		{"second array element", "product.variants.1.available", false, true},
+		{"missing key", "product.missing", nil, false},
+		{"index out of range", "product.variants.5.available", nil, false},
+		{"non-numeric index into array", "product.variants.foo", nil, false},
+		{"indexing into a non-container", "product.variants.0.available.nope", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookupJSONPath(data, tt.path)
+			if ok != tt.wantOK {
+				t.Fatalf("lookupJSONPath(%q) ok = %v, want %v", tt.path, ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("lookupJSONPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsStockValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  bool
+	}{
+		{"bool true", true, true},
+		{"bool false", false, false},
+		{"string true", "true", true},
+		{"string InStock", "InStock", true},
+		{"string in_stock", "in_stock", true},
+		{"string out of stock", "out of stock", false},
+		{"positive float", float64(5), true},
+		{"zero float", float64(0), false},
+		{"negative float", float64(-1), false},
+		{"unsupported type", []interface{}{}, false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStockValue(tt.value); got != tt.want {
+				t.Errorf("isStockValue(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Discord embed color for a restock alert (a green, matching Discord's own
+// "success" accent).
+const discordColorInStock = 0x57F287
+
+// DiscordMessage is the payload posted to a Discord webhook URL. It
+// deliberately has no "components" field: that's only accepted from an
+// application-owned webhook, and this is posted to a plain channel
+// incoming webhook (see config.example.yml), which rejects it with a 400.
+// The product link is carried on the embed's URL/title instead.
+type DiscordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []DiscordEmbed `json:"embeds,omitempty"`
+}
+
+// DiscordEmbed is a single rich embed attached to a Discord message.
+type DiscordEmbed struct {
+	Title     string              `json:"title,omitempty"`
+	URL       string              `json:"url,omitempty"`
+	Color     int                 `json:"color,omitempty"`
+	Timestamp string              `json:"timestamp,omitempty"`
+	Image     *DiscordEmbedImage  `json:"image,omitempty"`
+	Fields    []DiscordEmbedField `json:"fields,omitempty"`
+	Footer    *DiscordEmbedFooter `json:"footer,omitempty"`
+}
+
+type DiscordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// restockAlert carries everything buildRestockMessage needs to render an
+// embed for a single restock notification.
+type restockAlert struct {
+	ProductName string
+	ProductURL  string
+	UserID      string
+	Image       string
+	Price       string
+	History     []stateTransition
+	CheckCount  int
+	StartedAt   time.Time
+}
+
+// buildRestockMessage renders alert into a Discord message with a rich
+// embed: product image/price, restock history, and a "checked N times"
+// footer, linking straight to the product page via the embed title.
+func buildRestockMessage(alert restockAlert) DiscordMessage {
+	embed := DiscordEmbed{
+		Title:     fmt.Sprintf("🚨 %s is now IN STOCK!", alert.ProductName),
+		URL:       alert.ProductURL,
+		Color:     discordColorInStock,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Footer: &DiscordEmbedFooter{
+			Text: fmt.Sprintf("Checked %d times over %s", alert.CheckCount, time.Since(alert.StartedAt).Round(time.Minute)),
+		},
+	}
+
+	if alert.Image != "" {
+		embed.Image = &DiscordEmbedImage{URL: alert.Image}
+	}
+	if alert.Price != "" {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{Name: "Price", Value: alert.Price, Inline: true})
+	}
+	if history := formatHistory(alert.History); history != "" {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{Name: "Recent history", Value: history})
+	}
+
+	return DiscordMessage{
+		Content: fmt.Sprintf("<@%s>", alert.UserID),
+		Embeds:  []DiscordEmbed{embed},
+	}
+}
+
+// formatHistory renders transitions oldest-first as one line per flip.
+func formatHistory(transitions []stateTransition) string {
+	var lines string
+	for _, t := range transitions {
+		state := "Out of stock"
+		if t.InStock {
+			state = "In stock"
+		}
+		lines += fmt.Sprintf("%s — %s\n", t.Timestamp.Format("2006-01-02 15:04:05"), state)
+	}
+	return lines
+}
+
+// sendDiscordMessage posts msg to webhookURL.
+func sendDiscordMessage(webhookURL string, msg DiscordMessage) error {
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("error sending Discord notification: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 204 {
+		return fmt.Errorf("unexpected status code from Discord: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// historySize caps how many recent state transitions are kept per product,
+// so the restock-history embed field stays short.
+const historySize = 5
+
+// stateTransition records a single in-stock/out-of-stock flip.
+type stateTransition struct {
+	Timestamp time.Time
+	InStock   bool
+}
+
+// transitionHistory is a fixed-size ring buffer of a product's most recent
+// state transitions, safe for concurrent use.
+type transitionHistory struct {
+	mu      sync.Mutex
+	entries []stateTransition
+}
+
+func (h *transitionHistory) add(t stateTransition) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = append(h.entries, t)
+	if len(h.entries) > historySize {
+		h.entries = h.entries[len(h.entries)-historySize:]
+	}
+}
+
+func (h *transitionHistory) snapshot() []stateTransition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]stateTransition, len(h.entries))
+	copy(out, h.entries)
+	return out
+}
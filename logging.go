@@ -0,0 +1,23 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// setupLogger installs the process-wide slog logger, selecting a JSON or
+// text handler based on MONITOR_LOG_FORMAT ("json" or "text", default
+// "text").
+func setupLogger() {
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+
+	var handler slog.Handler
+	switch os.Getenv("MONITOR_LOG_FORMAT") {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	default:
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+}
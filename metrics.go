@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	checksTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eleven11_monitor_checks_total",
+			Help: "Total number of checks performed, per product.",
+		},
+		[]string{"product"},
+	)
+
+	stockState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eleven11_monitor_in_stock",
+			Help: "1 if the product is currently believed to be in stock, else 0.",
+		},
+		[]string{"product"},
+	)
+
+	proxyOutcomesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eleven11_monitor_proxy_outcomes_total",
+			Help: "Total proxy health check outcomes, per proxy.",
+		},
+		[]string{"proxy", "outcome"}, // outcome: success, failure
+	)
+
+	notificationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "eleven11_monitor_notifications_total",
+			Help: "Total Discord notification attempts, per product.",
+		},
+		[]string{"product", "outcome"}, // outcome: success, failure
+	)
+
+	checkLatencySeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "eleven11_monitor_check_latency_seconds",
+			Help:    "Latency of product check HTTP requests.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"product"},
+	)
+
+	lastCheckTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "eleven11_monitor_last_check_timestamp_seconds",
+			Help: "Unix timestamp of the last check, per product.",
+		},
+		[]string{"product"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		checksTotal,
+		stockState,
+		proxyOutcomesTotal,
+		notificationsTotal,
+		checkLatencySeconds,
+		lastCheckTimestamp,
+	)
+}
+
+// productHealth tracks when a product last completed a successful check,
+// so /healthz can report whether it's still being monitored on schedule.
+type productHealth struct {
+	lastSuccess time.Time
+
+	// maxInterval is the longest delay the product's scheduler can
+	// plausibly produce between checks right now, already widened for
+	// backoff and off-hours slowdown (see pollScheduler.maxInterval).
+	maxInterval time.Duration
+}
+
+// healthRegistry is the shared state /healthz reports on.
+type healthRegistry struct {
+	mu       sync.Mutex
+	products map[string]*productHealth
+}
+
+func newHealthRegistry() *healthRegistry {
+	return &healthRegistry{products: make(map[string]*productHealth)}
+}
+
+func (h *healthRegistry) register(name string, maxInterval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.products[name] = &productHealth{maxInterval: maxInterval}
+}
+
+func (h *healthRegistry) recordSuccess(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ph, ok := h.products[name]; ok {
+		ph.lastSuccess = time.Now()
+	}
+}
+
+// healthy reports overall readiness plus a per-product status string. A
+// product is unhealthy once it's gone more than 3 of its current max
+// intervals without a successful check. The max interval already accounts
+// for backoff and off-hours slowdown, so a product that's merely polling
+// slower than usual isn't mistaken for one that's stuck.
+func (h *healthRegistry) healthy() (bool, map[string]string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ok := true
+	details := make(map[string]string, len(h.products))
+	for name, ph := range h.products {
+		if ph.lastSuccess.IsZero() {
+			details[name] = "no successful check yet"
+			ok = false
+			continue
+		}
+
+		deadline := ph.lastSuccess.Add(3 * ph.maxInterval)
+		if time.Now().After(deadline) {
+			details[name] = fmt.Sprintf("no successful check since %s", ph.lastSuccess.Format(time.RFC3339))
+			ok = false
+			continue
+		}
+
+		details[name] = "ok"
+	}
+
+	return ok, details
+}
+
+// StartMetricsServer starts the embedded HTTP server exposing /metrics and
+// /healthz on port, in the background.
+func StartMetricsServer(port int, health *healthRegistry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		ok, details := health.healthy()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(details)
+	})
+
+	addr := fmt.Sprintf(":%d", port)
+
+	go func() {
+		slog.Info("starting metrics server", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("metrics server stopped", "error", err)
+		}
+	}()
+}
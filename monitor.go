@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// ProductMonitor watches a single product for restocks, running its own
+// collector and poll loop so a slow or erroring site can't block the rest
+// of the fleet.
+type ProductMonitor struct {
+	cfg       ProductConfig
+	collector *colly.Collector
+	detector  StockDetector
+	scheduler *pollScheduler
+	history   transitionHistory
+	health    *healthRegistry
+	store     StateStore
+
+	lastState    bool // true means out of stock
+	firstCheck   bool
+	lastNotified time.Time
+	checkCount   int
+	startedAt    time.Time
+
+	// image and price are scraped opportunistically from OpenGraph tags, if
+	// present, to enrich the restock notification embed.
+	image string
+	price string
+}
+
+// NewProductMonitor builds a monitor for cfg, routing its requests through
+// pool if one is given, reporting its liveness to health if given, and
+// reloading its last persisted state from store if given.
+func NewProductMonitor(cfg ProductConfig, pool *ProxyPool, health *healthRegistry, store StateStore) (*ProductMonitor, error) {
+	detector, err := NewStockDetector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error building stock detector for %q: %v", cfg.Name, err)
+	}
+
+	c := colly.NewCollector(
+		colly.AllowURLRevisit(),
+	)
+
+	if pool != nil {
+		c.SetProxyFunc(pool.ProxyFunc())
+	}
+
+	m := &ProductMonitor{
+		cfg:        cfg,
+		collector:  c,
+		detector:   detector,
+		scheduler:  newPollScheduler(cfg),
+		health:     health,
+		store:      store,
+		lastState:  true,
+		firstCheck: true,
+		startedAt:  time.Now(),
+	}
+
+	if health != nil {
+		health.register(cfg.Name, m.scheduler.maxInterval())
+	}
+
+	if store != nil {
+		state, found, err := store.Load(cfg.Name)
+		if err != nil {
+			return nil, fmt.Errorf("error loading persisted state for %q: %v", cfg.Name, err)
+		}
+		if found {
+			m.lastState = !state.LastInStock
+			m.firstCheck = false
+			m.lastNotified = state.LastNotified
+			m.checkCount = state.CheckCount
+			m.startedAt = state.StartedAt
+		}
+	}
+
+	detector.Register(c, m.handleResult)
+
+	c.OnHTML("meta[property='og:image']", func(e *colly.HTMLElement) {
+		m.image = e.Attr("content")
+	})
+	c.OnHTML("meta[property='product:price:amount']", func(e *colly.HTMLElement) {
+		m.price = e.Attr("content")
+	})
+
+	c.OnResponse(func(r *colly.Response) {
+		m.scheduler.onSuccess()
+		if m.health != nil {
+			m.health.recordSuccess(m.cfg.Name)
+		}
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		slog.Error("request failed", "product", m.cfg.Name, "url", r.Request.URL.String(), "error", err)
+
+		if isTransientStatus(r.StatusCode) {
+			m.scheduler.onTransientError()
+		}
+	})
+
+	return m, nil
+}
+
+// isTransientStatus reports whether status is the kind of rate-limit or
+// server error that should trigger backoff rather than being treated as a
+// one-off failure.
+func isTransientStatus(status int) bool {
+	return status == 429 || status == 403 || status >= 500
+}
+
+// Run polls the product on an adaptive schedule until the process exits.
+func (m *ProductMonitor) Run() {
+	for {
+		m.check()
+		time.Sleep(m.scheduler.next(time.Now()))
+	}
+}
+
+func (m *ProductMonitor) check() {
+	m.checkCount++
+	checksTotal.WithLabelValues(m.cfg.Name).Inc()
+	lastCheckTimestamp.WithLabelValues(m.cfg.Name).Set(float64(time.Now().Unix()))
+
+	start := time.Now()
+	err := m.collector.Visit(m.visitURL())
+	checkLatencySeconds.WithLabelValues(m.cfg.Name).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		slog.Error("error visiting site", "product", m.cfg.Name, "error", err)
+	}
+}
+
+// visitURL returns the URL the collector should request: the configured
+// JSON API endpoint for the json_api strategy, or the cache-busted product
+// page for everything else.
+func (m *ProductMonitor) visitURL() string {
+	if Strategy(m.cfg.Strategy) == StrategyJSONAPI {
+		return m.cfg.JSONAPIURL
+	}
+
+	cacheBuster := time.Now()
+	return fmt.Sprintf("%s?limit=%d", m.cfg.URL, cacheBuster.Unix())
+}
+
+// handleResult is called by the product's StockDetector once per check with
+// the detected availability.
+func (m *ProductMonitor) handleResult(inStock bool, raw string) {
+	defer m.persist()
+
+	if inStock {
+		stockState.WithLabelValues(m.cfg.Name).Set(1)
+	} else {
+		stockState.WithLabelValues(m.cfg.Name).Set(0)
+	}
+
+	if !inStock {
+		slog.Info("out of stock", "product", m.cfg.Name, "availability", raw)
+		if !m.lastState && !m.firstCheck {
+			m.history.add(stateTransition{Timestamp: time.Now(), InStock: false})
+			m.scheduler.onStateChange()
+		}
+		m.lastState = true
+		m.firstCheck = false
+		return
+	}
+
+	slog.Info("in stock", "product", m.cfg.Name, "availability", raw)
+
+	if m.lastState && !m.firstCheck {
+		m.history.add(stateTransition{Timestamp: time.Now(), InStock: true})
+		m.scheduler.onStateChange()
+
+		if time.Since(m.lastNotified) >= m.cfg.NotifyCooldown {
+			msg := buildRestockMessage(restockAlert{
+				ProductName: m.cfg.Name,
+				ProductURL:  m.cfg.URL,
+				UserID:      m.cfg.DiscordUserID,
+				Image:       m.image,
+				Price:       m.price,
+				History:     m.history.snapshot(),
+				CheckCount:  m.checkCount,
+				StartedAt:   m.startedAt,
+			})
+			if err := sendDiscordMessage(m.cfg.DiscordWebhookURL, msg); err != nil {
+				notificationsTotal.WithLabelValues(m.cfg.Name, "failure").Inc()
+				slog.Error("error sending Discord notification", "product", m.cfg.Name, "error", err)
+			} else {
+				notificationsTotal.WithLabelValues(m.cfg.Name, "success").Inc()
+				m.lastNotified = time.Now()
+			}
+		}
+	}
+
+	m.lastState = false
+	m.firstCheck = false
+}
+
+// persist saves the monitor's current state so a restart can pick up where
+// it left off instead of re-notifying or losing check history.
+func (m *ProductMonitor) persist() {
+	if m.store == nil {
+		return
+	}
+
+	state := ProductState{
+		LastInStock:  !m.lastState,
+		LastNotified: m.lastNotified,
+		CheckCount:   m.checkCount,
+		StartedAt:    m.startedAt,
+	}
+	if err := m.store.Save(m.cfg.Name, state); err != nil {
+		slog.Error("error persisting state", "product", m.cfg.Name, "error", err)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// formatProxy converts "ip:port:user:pass" into a colly-compatible
+// "http://user:pass@ip:port" URL.
+func formatProxy(proxyStr string) string {
+	proxyStr = strings.TrimSpace(proxyStr)
+	if proxyStr == "" {
+		return ""
+	}
+
+	parts := strings.Split(proxyStr, ":")
+	if len(parts) != 4 {
+		return ""
+	}
+
+	ip := parts[0]
+	port := parts[1]
+	user := parts[2]
+	pass := parts[3]
+
+	return fmt.Sprintf("http://%s:%s@%s:%s", user, pass, ip, port)
+}
+
+// readProxiesFromFile loads and formats one proxy per line from filename.
+func readProxiesFromFile(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error opening proxy file: %v", err)
+	}
+	defer file.Close()
+
+	var proxies []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		proxy := formatProxy(scanner.Text())
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading proxy file: %v", err)
+	}
+
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("no valid proxies found in file")
+	}
+
+	return proxies, nil
+}
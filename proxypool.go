@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// proxyState tracks health statistics for a single proxy.
+type proxyState struct {
+	rawURL string
+	parsed *url.URL
+
+	mu               sync.Mutex
+	successCount     int
+	failureCount     int
+	consecutiveFails int
+	totalLatency     time.Duration
+	latencySamples   int
+	quarantined      bool
+	quarantinedUntil time.Time
+}
+
+// healthy reports whether the proxy is in rotation. A quarantined proxy
+// only leaves quarantine via recordSuccess, once its cooldown has elapsed
+// and a recheck has actually passed — cooldown expiring on its own isn't
+// enough, since checkAll keeps probing quarantined proxies every
+// CheckInterval regardless of rotation status.
+func (s *proxyState) healthy() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.quarantined
+}
+
+func (s *proxyState) averageLatency() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latencySamples == 0 {
+		return 0
+	}
+	return s.totalLatency / time.Duration(s.latencySamples)
+}
+
+// recordSuccess records a passing health check. A proxy already in
+// quarantine is only readmitted once its cooldown has elapsed, so a lucky
+// response mid-cooldown doesn't put it straight back into rotation.
+func (s *proxyState) recordSuccess(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.successCount++
+	s.totalLatency += latency
+	s.latencySamples++
+
+	if !s.quarantined {
+		s.consecutiveFails = 0
+		return
+	}
+	if time.Now().After(s.quarantinedUntil) {
+		s.quarantined = false
+		s.consecutiveFails = 0
+	}
+}
+
+func (s *proxyState) recordFailure(threshold int, cooldown time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failureCount++
+	s.consecutiveFails++
+	if s.consecutiveFails >= threshold {
+		s.quarantined = true
+		s.quarantinedUntil = time.Now().Add(cooldown)
+	}
+}
+
+// ProxyPool manages a set of proxies, periodically health-checking them
+// against a known-good URL, quarantining proxies that fail too often, and
+// re-testing them after a cooldown before returning them to rotation.
+type ProxyPool struct {
+	cfg     ProxyPoolConfig
+	proxies []*proxyState
+
+	mu   sync.Mutex
+	next int // round-robin cursor
+}
+
+// NewProxyPool builds a pool from rawProxyURLs (already in
+// "http://user:pass@ip:port" form) and starts its background health
+// checker.
+func NewProxyPool(cfg ProxyPoolConfig, rawProxyURLs []string) (*ProxyPool, error) {
+	if len(rawProxyURLs) == 0 {
+		return nil, fmt.Errorf("proxy pool requires at least one proxy")
+	}
+
+	pool := &ProxyPool{cfg: cfg}
+
+	for _, raw := range rawProxyURLs {
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %v", raw, err)
+		}
+		pool.proxies = append(pool.proxies, &proxyState{rawURL: raw, parsed: parsed})
+	}
+
+	go pool.healthCheckLoop()
+
+	return pool, nil
+}
+
+func (p *ProxyPool) healthCheckLoop() {
+	p.checkAll()
+
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.checkAll()
+	}
+}
+
+func (p *ProxyPool) checkAll() {
+	for _, state := range p.proxies {
+		go p.checkOne(state)
+	}
+}
+
+func (p *ProxyPool) checkOne(state *proxyState) {
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{Proxy: http.ProxyURL(state.parsed)},
+	}
+
+	// state.parsed.Host (not rawURL) is used as the metric label so
+	// credentials never end up in Prometheus output.
+	label := state.parsed.Host
+
+	start := time.Now()
+	resp, err := client.Get(p.cfg.CheckURL)
+	if err != nil {
+		state.recordFailure(p.cfg.ErrorThreshold, p.cfg.QuarantineCooldown)
+		proxyOutcomesTotal.WithLabelValues(label, "failure").Inc()
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		state.recordFailure(p.cfg.ErrorThreshold, p.cfg.QuarantineCooldown)
+		proxyOutcomesTotal.WithLabelValues(label, "failure").Inc()
+		return
+	}
+
+	state.recordSuccess(time.Since(start))
+	proxyOutcomesTotal.WithLabelValues(label, "success").Inc()
+}
+
+// ProxyFunc returns a colly-compatible proxy selector backed by the pool's
+// configured rotation strategy, skipping quarantined proxies.
+func (p *ProxyPool) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(_ *http.Request) (*url.URL, error) {
+		state := p.pick()
+		if state == nil {
+			return nil, fmt.Errorf("no proxies available")
+		}
+		return state.parsed, nil
+	}
+}
+
+// healthyProxies returns the proxies that passed their last check, or the
+// full pool if every proxy is currently quarantined, so requests don't
+// stall entirely during a bad patch.
+func (p *ProxyPool) healthyProxies() []*proxyState {
+	var healthy []*proxyState
+	for _, state := range p.proxies {
+		if state.healthy() {
+			healthy = append(healthy, state)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.proxies
+	}
+	return healthy
+}
+
+func (p *ProxyPool) pick() *proxyState {
+	candidates := p.healthyProxies()
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	switch p.cfg.Strategy {
+	case ProxyStrategyRandom:
+		return candidates[rand.Intn(len(candidates))]
+
+	case ProxyStrategyLeastLatency:
+		best := candidates[0]
+		bestLatency := best.averageLatency()
+		for _, c := range candidates[1:] {
+			l := c.averageLatency()
+			if l > 0 && (bestLatency == 0 || l < bestLatency) {
+				best, bestLatency = c, l
+			}
+		}
+		return best
+
+	case ProxyStrategyWeighted:
+		return p.pickWeighted(candidates)
+
+	default: // ProxyStrategyRoundRobin
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		state := candidates[p.next%len(candidates)]
+		p.next++
+		return state
+	}
+}
+
+// pickWeighted favours proxies with more recorded successes, giving
+// untested proxies a base weight so they still get a turn.
+func (p *ProxyPool) pickWeighted(candidates []*proxyState) *proxyState {
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, c := range candidates {
+		c.mu.Lock()
+		w := float64(c.successCount) + 1
+		c.mu.Unlock()
+		weights[i] = w
+		total += w
+	}
+
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return candidates[i]
+		}
+		r -= w
+	}
+
+	return candidates[len(candidates)-1]
+}
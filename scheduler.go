@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// pollScheduler computes the delay before a product's next poll, layering
+// jitter, error backoff, an off-hours slowdown and a post-change confirm
+// burst on top of the product's base PollInterval. A tight, uniform poll
+// loop is a fingerprint that gets proxies banned quickly; this spreads it
+// out and backs off under pressure instead.
+type pollScheduler struct {
+	cfg ProductConfig
+
+	consecutiveErrors int
+	confirmRemaining  int
+}
+
+func newPollScheduler(cfg ProductConfig) *pollScheduler {
+	return &pollScheduler{cfg: cfg}
+}
+
+// onSuccess resets the error backoff after a clean response.
+func (s *pollScheduler) onSuccess() {
+	s.consecutiveErrors = 0
+}
+
+// onTransientError records a 429/403/5xx so the next interval backs off.
+func (s *pollScheduler) onTransientError() {
+	s.consecutiveErrors++
+}
+
+// onStateChange starts a short burst of faster polling to confirm the
+// change isn't a flap.
+func (s *pollScheduler) onStateChange() {
+	s.confirmRemaining = s.cfg.ConfirmChecks
+}
+
+// next returns the delay before the next check, relative to now.
+func (s *pollScheduler) next(now time.Time) time.Duration {
+	if s.confirmRemaining > 0 {
+		s.confirmRemaining--
+		return s.jitter(s.cfg.ConfirmInterval)
+	}
+
+	interval := s.backoff(s.cfg.PollInterval)
+	if s.cfg.OffHoursMultiplier > 0 && s.inOffHours(now) {
+		interval = time.Duration(float64(interval) * s.cfg.OffHoursMultiplier)
+	}
+
+	return s.jitter(interval)
+}
+
+// maxInterval returns the longest delay next can plausibly produce for the
+// product right now: PollInterval widened by the backoff ceiling and, if
+// configured, further slowed by the off-hours multiplier. Callers that need
+// a liveness deadline should use this instead of the base PollInterval, so
+// a product that's legitimately backing off or inside its off-hours window
+// doesn't look stuck.
+func (s *pollScheduler) maxInterval() time.Duration {
+	interval := s.cfg.PollInterval
+	if s.cfg.BackoffCeiling > interval {
+		interval = s.cfg.BackoffCeiling
+	}
+	if s.cfg.OffHoursMultiplier > 1 {
+		interval = time.Duration(float64(interval) * s.cfg.OffHoursMultiplier)
+	}
+	return interval
+}
+
+// backoff doubles base per consecutive transient error, capped at
+// BackoffCeiling, and resets as soon as a check succeeds.
+func (s *pollScheduler) backoff(base time.Duration) time.Duration {
+	ceiling := s.cfg.BackoffCeiling
+	if ceiling <= 0 || ceiling < base {
+		ceiling = base
+	}
+
+	interval := base
+	for i := 0; i < s.consecutiveErrors && interval < ceiling; i++ {
+		interval *= 2
+	}
+	if interval > ceiling {
+		interval = ceiling
+	}
+	return interval
+}
+
+// jitter adds up to ±JitterPercent% randomness to interval. LoadConfig
+// rejects JitterPercent >= 100, so factor never reaches zero or goes
+// negative and collapses the interval.
+func (s *pollScheduler) jitter(interval time.Duration) time.Duration {
+	if s.cfg.JitterPercent <= 0 {
+		return interval
+	}
+
+	factor := 1 + (rand.Float64()*2-1)*s.cfg.JitterPercent/100
+	return time.Duration(float64(interval) * factor)
+}
+
+// inOffHours reports whether now falls inside the product's configured
+// off-hours window, which may wrap past midnight (e.g. 22:00-07:00).
+func (s *pollScheduler) inOffHours(now time.Time) bool {
+	if s.cfg.OffHoursStart == "" || s.cfg.OffHoursEnd == "" {
+		return false
+	}
+
+	start, err := time.Parse("15:04", s.cfg.OffHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", s.cfg.OffHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	nowMinutes := now.Hour()*60 + now.Minute()
+	startMinutes := start.Hour()*60 + start.Minute()
+	endMinutes := end.Hour()*60 + end.Minute()
+
+	if startMinutes <= endMinutes {
+		return nowMinutes >= startMinutes && nowMinutes < endMinutes
+	}
+	return nowMinutes >= startMinutes || nowMinutes < endMinutes
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPollSchedulerBackoff(t *testing.T) {
+	tests := []struct {
+		name              string
+		base              time.Duration
+		ceiling           time.Duration
+		consecutiveErrors int
+		want              time.Duration
+	}{
+		{"no errors returns base", 30 * time.Second, 10 * time.Minute, 0, 30 * time.Second},
+		{"one error doubles", 30 * time.Second, 10 * time.Minute, 1, time.Minute},
+		{"several errors double repeatedly", 30 * time.Second, 10 * time.Minute, 3, 4 * time.Minute},
+		{"clamps at ceiling", 30 * time.Second, 2 * time.Minute, 10, 2 * time.Minute},
+		{"ceiling below base falls back to base", 30 * time.Second, 10 * time.Second, 5, 30 * time.Second},
+		{"zero ceiling falls back to base", 30 * time.Second, 0, 5, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPollScheduler(ProductConfig{BackoffCeiling: tt.ceiling})
+			s.consecutiveErrors = tt.consecutiveErrors
+
+			if got := s.backoff(tt.base); got != tt.want {
+				t.Errorf("backoff(%v) with %d errors = %v, want %v", tt.base, tt.consecutiveErrors, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPollSchedulerInOffHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		now        string
+		want       bool
+	}{
+		{"unset window is never off-hours", "", "", "02:00", false},
+		{"inside same-day window", "09:00", "17:00", "12:00", true},
+		{"before same-day window", "09:00", "17:00", "08:59", false},
+		{"at same-day window start is in", "09:00", "17:00", "09:00", true},
+		{"at same-day window end is out", "09:00", "17:00", "17:00", false},
+		{"wraps past midnight, late night", "23:00", "07:00", "23:30", true},
+		{"wraps past midnight, early morning", "23:00", "07:00", "03:00", true},
+		{"wraps past midnight, at end boundary", "23:00", "07:00", "07:00", false},
+		{"wraps past midnight, daytime outside window", "23:00", "07:00", "12:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newPollScheduler(ProductConfig{OffHoursStart: tt.start, OffHoursEnd: tt.end})
+
+			now, err := time.Parse("15:04", tt.now)
+			if err != nil {
+				t.Fatalf("invalid test time %q: %v", tt.now, err)
+			}
+
+			if got := s.inOffHours(now); got != tt.want {
+				t.Errorf("inOffHours(%s) with window %s-%s = %v, want %v", tt.now, tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+// ProductState is a product's persisted state: its last known availability,
+// when it was last notified, how many checks it's seen, and when it was
+// first seen. Reloading this on startup means a restart doesn't treat the
+// next observation as a first check (suppressing a legitimate restock
+// alert), re-fire a notification for a product that was already in stock,
+// or reset the "checked N times over Xh" history to zero.
+type ProductState struct {
+	LastInStock  bool      `json:"last_in_stock"`
+	LastNotified time.Time `json:"last_notified"`
+	CheckCount   int       `json:"check_count"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// StateStore persists ProductState across restarts.
+type StateStore interface {
+	Load(product string) (state ProductState, found bool, err error)
+	Save(product string, state ProductState) error
+	Close() error
+}
+
+// NewStateStore builds the StateStore configured by cfg.
+func NewStateStore(cfg StateStoreConfig) (StateStore, error) {
+	switch cfg.Backend {
+	case "", StateStoreFile:
+		return newFileStateStore(cfg.Path)
+	case StateStoreBolt:
+		return newBoltStateStore(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown state store backend %q", cfg.Backend)
+	}
+}
+
+// fileStateStore persists every product's state in a single JSON file.
+type fileStateStore struct {
+	mu    sync.Mutex
+	path  string
+	cache map[string]ProductState
+}
+
+func newFileStateStore(path string) (*fileStateStore, error) {
+	s := &fileStateStore{path: path, cache: make(map[string]ProductState)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("error reading state file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &s.cache); err != nil {
+		return nil, fmt.Errorf("error parsing state file: %v", err)
+	}
+
+	return s, nil
+}
+
+func (s *fileStateStore) Load(product string) (ProductState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.cache[product]
+	return state, ok, nil
+}
+
+func (s *fileStateStore) Save(product string, state ProductState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache[product] = state
+
+	data, err := json.MarshalIndent(s.cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling state: %v", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("error writing state file: %v", err)
+	}
+
+	return nil
+}
+
+func (s *fileStateStore) Close() error { return nil }
+
+// boltStateStore persists every product's state as a key in a single
+// BoltDB bucket.
+type boltStateStore struct {
+	db *bbolt.DB
+}
+
+var stateBucket = []byte("products")
+
+func newBoltStateStore(path string) (*boltStateStore, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening state db: %v", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(stateBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing state db: %v", err)
+	}
+
+	return &boltStateStore{db: db}, nil
+}
+
+func (s *boltStateStore) Load(product string) (ProductState, bool, error) {
+	var state ProductState
+	var found bool
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		data := tx.Bucket(stateBucket).Get([]byte(product))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &state)
+	})
+
+	return state, found, err
+}
+
+func (s *boltStateStore) Save(product string, state ProductState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("error marshaling state: %v", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(stateBucket).Put([]byte(product), data)
+	})
+}
+
+func (s *boltStateStore) Close() error { return s.db.Close() }